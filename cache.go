@@ -0,0 +1,481 @@
+package redtable
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// CacheStats reports cumulative counters for a CachingClient's LRU cache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type cacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// lruCache is a size-bounded, optionally TTL-aware cache of []byte values
+// keyed by string. It is not safe for concurrent use on its own;
+// CachingClient guards it with its own mutex.
+type lruCache struct {
+	capacity int
+	ttl      time.Duration
+
+	ll    *list.List
+	items map[string]*list.Element
+
+	stats CacheStats
+}
+
+func newLRUCache(capacity int, ttl time.Duration) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (l *lruCache) get(key string) ([]byte, bool) {
+	el, ok := l.items[key]
+	if !ok {
+		l.stats.Misses++
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if l.ttl > 0 && time.Now().After(entry.expiresAt) {
+		l.removeElement(el)
+		l.stats.Misses++
+		return nil, false
+	}
+
+	l.ll.MoveToFront(el)
+	l.stats.Hits++
+	return entry.value, true
+}
+
+func (l *lruCache) set(key string, value []byte) {
+	if el, ok := l.items[key]; ok {
+		l.ll.MoveToFront(el)
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiresAt = l.expiryFor()
+		return
+	}
+
+	el := l.ll.PushFront(&cacheEntry{key: key, value: value, expiresAt: l.expiryFor()})
+	l.items[key] = el
+
+	if l.capacity > 0 && l.ll.Len() > l.capacity {
+		l.removeOldest()
+	}
+}
+
+func (l *lruCache) expiryFor() time.Time {
+	if l.ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(l.ttl)
+}
+
+func (l *lruCache) removeOldest() {
+	if el := l.ll.Back(); el != nil {
+		l.removeElement(el)
+		l.stats.Evictions++
+	}
+}
+
+func (l *lruCache) removeElement(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.items, el.Value.(*cacheEntry).key)
+}
+
+func (l *lruCache) del(key string) {
+	if el, ok := l.items[key]; ok {
+		l.removeElement(el)
+	}
+}
+
+// delPrefix removes every cached entry whose key starts with prefix.
+func (l *lruCache) delPrefix(prefix string) {
+	for key, el := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.removeElement(el)
+		}
+	}
+}
+
+// Cache key kinds: a cached reply's meaning depends on which op produced
+// it (a raw HGET value and an HEXISTS boolean must never collide), so
+// every key is namespaced by kind as well as table and field.
+const (
+	cacheKindHGet      = 'h'
+	cacheKindHExists   = 'e'
+	cacheKindSIsMember = 's'
+	cacheKindLIndex    = 'l'
+)
+
+func cacheKeyFor(kind byte, tableName string, field interface{}) string {
+	return fmt.Sprintf("%s\x00%c\x00%v", tableName, kind, field)
+}
+
+func tablePrefix(tableName string) string {
+	return tableName + "\x00"
+}
+
+func kindPrefix(kind byte, tableName string) string {
+	return fmt.Sprintf("%s\x00%c\x00", tableName, kind)
+}
+
+// CachingClient wraps *Client and fronts reads (HGet, HMGet, HExists,
+// SIsMember, LIndex) with a size-bounded, TTL-aware in-process LRU cache,
+// invalidating the affected entries on the corresponding writes (HSet,
+// HDel, HPop, HMove, Del, SAdd, SRem, SPop, LPush, LPop).
+type CachingClient struct {
+	*Client
+
+	mu    sync.Mutex
+	cache *lruCache
+}
+
+// NewCachingClient wraps client with an LRU cache holding up to capacity
+// entries, each valid for ttl (ttl <= 0 means entries never expire on
+// their own, only on eviction or invalidation).
+func NewCachingClient(client *Client, capacity int, ttl time.Duration) *CachingClient {
+	return &CachingClient{
+		Client: client,
+		cache:  newLRUCache(capacity, ttl),
+	}
+}
+
+// Stats reports the cache's cumulative hit/miss/eviction counters.
+func (cc *CachingClient) Stats() CacheStats {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.cache.stats
+}
+
+func replyToBytes(reply interface{}) ([]byte, bool) {
+	switch v := reply.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		return nil, false
+	}
+}
+
+func (cc *CachingClient) HGet(tableName string, key interface{}) (interface{}, error) {
+	cacheKey := cacheKeyFor(cacheKindHGet, tableName, key)
+
+	cc.mu.Lock()
+	cached, ok := cc.cache.get(cacheKey)
+	cc.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	reply, err := cc.Client.HGet(tableName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if b, ok := replyToBytes(reply); ok {
+		cc.mu.Lock()
+		cc.cache.set(cacheKey, b)
+		cc.mu.Unlock()
+	}
+	return reply, nil
+}
+
+func (cc *CachingClient) HMGet(tableName string, keys ...interface{}) ([]interface{}, error) {
+	results := make([]interface{}, len(keys))
+	var missingIdx []int
+	var missingKeys []interface{}
+
+	cc.mu.Lock()
+	for i, key := range keys {
+		if cached, ok := cc.cache.get(cacheKeyFor(cacheKindHGet, tableName, key)); ok {
+			results[i] = cached
+		} else {
+			missingIdx = append(missingIdx, i)
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	cc.mu.Unlock()
+
+	if len(missingKeys) == 0 {
+		return results, nil
+	}
+
+	fetched, err := cc.Client.HMGet(tableName, missingKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	for i, idx := range missingIdx {
+		results[idx] = fetched[i]
+		if b, ok := replyToBytes(fetched[i]); ok {
+			cc.cache.set(cacheKeyFor(cacheKindHGet, tableName, missingKeys[i]), b)
+		}
+	}
+	cc.mu.Unlock()
+
+	return results, nil
+}
+
+func (cc *CachingClient) HExists(tableName string, key interface{}) (bool, error) {
+	cacheKey := cacheKeyFor(cacheKindHExists, tableName, key)
+
+	cc.mu.Lock()
+	cached, ok := cc.cache.get(cacheKey)
+	cc.mu.Unlock()
+	if ok {
+		return len(cached) == 1 && cached[0] == 1, nil
+	}
+
+	exists, err := cc.Client.HExists(tableName, key)
+	if err != nil {
+		return false, err
+	}
+
+	cc.mu.Lock()
+	cc.cache.set(cacheKey, boolToByte(exists))
+	cc.mu.Unlock()
+	return exists, nil
+}
+
+func (cc *CachingClient) SIsMember(tableName string, key interface{}) (bool, error) {
+	cacheKey := cacheKeyFor(cacheKindSIsMember, tableName, key)
+
+	cc.mu.Lock()
+	cached, ok := cc.cache.get(cacheKey)
+	cc.mu.Unlock()
+	if ok {
+		return len(cached) == 1 && cached[0] == 1, nil
+	}
+
+	exists, err := cc.Client.SIsMember(tableName, key)
+	if err != nil {
+		return false, err
+	}
+
+	cc.mu.Lock()
+	cc.cache.set(cacheKey, boolToByte(exists))
+	cc.mu.Unlock()
+	return exists, nil
+}
+
+func (cc *CachingClient) LIndex(tableName string, index int64) (interface{}, error) {
+	cacheKey := cacheKeyFor(cacheKindLIndex, tableName, index)
+
+	cc.mu.Lock()
+	cached, ok := cc.cache.get(cacheKey)
+	cc.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	reply, err := cc.Client.LIndex(tableName, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if b, ok := replyToBytes(reply); ok {
+		cc.mu.Lock()
+		cc.cache.set(cacheKey, b)
+		cc.mu.Unlock()
+	}
+	return reply, nil
+}
+
+func boolToByte(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func (cc *CachingClient) invalidateField(tableName string, key interface{}) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.cache.del(cacheKeyFor(cacheKindHGet, tableName, key))
+	cc.cache.del(cacheKeyFor(cacheKindHExists, tableName, key))
+}
+
+func (cc *CachingClient) invalidateKind(kind byte, tableName string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.cache.delPrefix(kindPrefix(kind, tableName))
+}
+
+func (cc *CachingClient) invalidateTable(tableName string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.cache.delPrefix(tablePrefix(tableName))
+}
+
+func (cc *CachingClient) HSet(tableName string, key, value interface{}) (interface{}, error) {
+	reply, err := cc.Client.HSet(tableName, key, value)
+	if err == nil {
+		cc.invalidateField(tableName, key)
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) HDel(tableName string, key interface{}) (interface{}, error) {
+	reply, err := cc.Client.HDel(tableName, key)
+	if err == nil {
+		cc.invalidateField(tableName, key)
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) HPop(tableName string, key interface{}) (interface{}, error) {
+	reply, err := cc.Client.HPop(tableName, key)
+	if err == nil {
+		cc.invalidateField(tableName, key)
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) HMove(hashTableName1, hashTableName2 string, key interface{}) (interface{}, error) {
+	reply, err := cc.Client.HMove(hashTableName1, hashTableName2, key)
+	if err == nil {
+		cc.invalidateField(hashTableName1, key)
+		cc.invalidateField(hashTableName2, key)
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) Del(firstTable string, otherTables ...interface{}) (interface{}, error) {
+	reply, err := cc.Client.Del(firstTable, otherTables...)
+	if err == nil {
+		cc.invalidateTable(firstTable)
+		for _, t := range otherTables {
+			cc.invalidateTable(fmt.Sprintf("%v", t))
+		}
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) SAdd(tableName string, items ...interface{}) (interface{}, error) {
+	reply, err := cc.Client.SAdd(tableName, items...)
+	if err == nil {
+		cc.mu.Lock()
+		for _, item := range items {
+			cc.cache.del(cacheKeyFor(cacheKindSIsMember, tableName, item))
+		}
+		cc.mu.Unlock()
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) SRem(tableName string, key interface{}, otherKeys ...interface{}) (interface{}, error) {
+	reply, err := cc.Client.SRem(tableName, key, otherKeys...)
+	if err == nil {
+		cc.mu.Lock()
+		cc.cache.del(cacheKeyFor(cacheKindSIsMember, tableName, key))
+		for _, k := range otherKeys {
+			cc.cache.del(cacheKeyFor(cacheKindSIsMember, tableName, k))
+		}
+		cc.mu.Unlock()
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) SPop(tableName string) (interface{}, error) {
+	reply, err := cc.Client.SPop(tableName)
+	if err == nil {
+		// SPOP removes an arbitrary member, so invalidate every cached
+		// SIsMember result for this table rather than guessing which one.
+		cc.invalidateKind(cacheKindSIsMember, tableName)
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) LPush(tableName string, values ...interface{}) (interface{}, error) {
+	reply, err := cc.Client.LPush(tableName, values...)
+	if err == nil {
+		cc.invalidateKind(cacheKindLIndex, tableName)
+	}
+	return reply, err
+}
+
+func (cc *CachingClient) LPop(tableName string) (interface{}, error) {
+	reply, err := cc.Client.LPop(tableName)
+	if err == nil {
+		cc.invalidateKind(cacheKindLIndex, tableName)
+	}
+	return reply, err
+}
+
+// WatchInvalidation subscribes to channel (e.g. the keyspace-notification
+// pattern "__keyspace@0__:*") on a dedicated connection dialed via dialFn,
+// and evicts a table's cached entries whenever it publishes a write event
+// (hset, hdel, del, lpush, lpop, sadd, srem, spop). It blocks until ctx is
+// done or the subscription errors, so callers typically run it in its own
+// goroutine; this is how multiple CachingClient replicas stay coherent.
+func (cc *CachingClient) WatchInvalidation(ctx context.Context, dialFn func() (redis.Conn, error), channel string) error {
+	conn, err := dialFn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	psc := redis.PubSubConn{Conn: conn}
+	if err := psc.PSubscribe(channel); err != nil {
+		return err
+	}
+	defer psc.Close()
+
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+		close(done)
+	}()
+
+	for {
+		switch msg := psc.Receive().(type) {
+		case redis.PMessage:
+			cc.handleKeyspaceEvent(msg.Channel, string(msg.Data))
+		case error:
+			select {
+			case <-done:
+				return nil
+			default:
+				return msg
+			}
+		}
+	}
+}
+
+// handleKeyspaceEvent evicts the cached entries for the table named in a
+// "__keyspace@0__:<table>" channel when event is a write we front a read
+// for.
+func (cc *CachingClient) handleKeyspaceEvent(channel, event string) {
+	idx := strings.Index(channel, ":")
+	if idx < 0 {
+		return
+	}
+	tableName := channel[idx+1:]
+
+	switch event {
+	case "hset", "hdel", "del", "lpush", "lpop", "sadd", "srem", "spop":
+		cc.invalidateTable(tableName)
+	}
+}
@@ -0,0 +1,74 @@
+package redtable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/odeke-em/go-uuid"
+)
+
+func TestLRUCacheEvictsOldest(t *testing.T) {
+	cache := newLRUCache(2, 0)
+	cache.set("a", []byte("1"))
+	cache.set("b", []byte("2"))
+	cache.set("c", []byte("3"))
+
+	if _, ok := cache.get("a"); ok {
+		t.Errorf("expected %q to have been evicted", "a")
+	}
+	if _, ok := cache.get("b"); !ok {
+		t.Errorf("expected %q to still be cached", "b")
+	}
+	if got, want := cache.stats.Evictions, uint64(1); got != want {
+		t.Errorf("Evictions=%d want=%d", got, want)
+	}
+}
+
+func TestLRUCacheTTLExpiry(t *testing.T) {
+	cache := newLRUCache(0, 1*time.Millisecond)
+	cache.set("a", []byte("1"))
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.get("a"); ok {
+		t.Errorf("expected %q to have expired", "a")
+	}
+}
+
+func TestCachingClientHGetHitsAndInvalidates(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	cc := NewCachingClient(client, 64, 0)
+	defer cc.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer cc.Del(tableName)
+
+	if _, err := cc.HSet(tableName, "k1", "v1"); err != nil {
+		t.Fatalf("HSet err=%v", err)
+	}
+
+	if _, err := cc.HGet(tableName, "k1"); err != nil {
+		t.Fatalf("HGet err=%v", err)
+	}
+	if _, err := cc.HGet(tableName, "k1"); err != nil {
+		t.Fatalf("HGet err=%v", err)
+	}
+
+	stats := cc.Stats()
+	if stats.Hits == 0 {
+		t.Errorf("expected at least one cache hit, got stats=%+v", stats)
+	}
+
+	if _, err := cc.HSet(tableName, "k1", "v2"); err != nil {
+		t.Fatalf("HSet err=%v", err)
+	}
+	got, err := cc.HGet(tableName, "k1")
+	if err != nil {
+		t.Fatalf("HGet err=%v", err)
+	}
+	if want := "v2"; string(got.([]byte)) != want {
+		t.Errorf("got=%q want=%q after invalidation", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package redtable
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -29,6 +30,7 @@ const (
 	opSPop    = "SPOP"
 	opSRem    = "SREM"
 	opSLen    = "SLEN"
+	opWatch   = "WATCH"
 
 	opSMembers  = "SMEMBERS"
 	opSIsMember = "SISMEMBER"
@@ -112,21 +114,22 @@ func (c *Client) conn() redis.Conn {
 	c.connMu.Lock()
 	defer c.connMu.Unlock()
 
-	if c._curConn == nil {
-		c._curConn = c.poolConn()
-		return c._curConn
-	}
+	for {
+		if c._curConn == nil {
+			c._curConn = c.poolConn()
+			return c._curConn
+		}
 
-	cn := c._curConn
-	if cn.Err() == nil {
-		// Go to go then
-		return cn
-	}
+		cn := c._curConn
+		if cn.Err() == nil {
+			// Go to go then
+			return cn
+		}
 
-	// Otherwise time to refresh it
-	cn.Close()
-	c._curConn = nil
-	return c.conn()
+		// Otherwise time to refresh it
+		cn.Close()
+		c._curConn = nil
+	}
 }
 
 var errInvalidRedisURLs = errors.New("expecting at least one valid URL connection URL")
@@ -149,92 +152,59 @@ func (c *Client) ConnErr() error {
 	return nil
 }
 
-func (c *Client) doHashOp(opName, hashTableName string, args ...interface{}) ([]interface{}, error) {
-	if err := c.conn().Send(opMulti); err != nil {
-		return nil, err
-	}
-
+// doHashOp issues opName directly against the connection as a single
+// round trip (conn.Do), rather than wrapping it in its own MULTI/EXEC.
+// Callers that need several ops sent in one round trip should use
+// Pipeline, and those that need them to commit atomically should use Tx.
+func (c *Client) doHashOp(opName, hashTableName string, args ...interface{}) (interface{}, error) {
 	allArgs := append([]interface{}{hashTableName}, args...)
-	if sendErr := c.conn().Send(opName, allArgs...); sendErr != nil {
-		return nil, sendErr
-	}
-	return redis.Values(c.conn().Do(opExec))
+	return c.conn().Do(opName, allArgs...)
 }
 
 func (c *Client) HSet(hashTableName string, key, value interface{}) (interface{}, error) {
-	replies, err := c.doHashOp(opHSet, hashTableName, key, value)
-	if err != nil {
-		return nil, err
-	}
-	return replies[0], nil
-}
-
-func multiKeysOp(c *Client, opName, hashTableName string, keys ...interface{}) ([]interface{}, error) {
-	return c.doHashOp(opName, hashTableName, keys...)
-}
-
-func byKeyOp(c *Client, opName, hashTableName string, keys ...interface{}) (interface{}, error) {
-	replies, err := multiKeysOp(c, opName, hashTableName, keys...)
-	if err != nil {
-		return nil, err
-	}
-	return replies[0], nil
+	return c.doHashOp(opHSet, hashTableName, key, value)
 }
 
 func (c *Client) HGet(hashTableName string, key interface{}) (interface{}, error) {
-	return byKeyOp(c, opHGet, hashTableName, key)
+	return c.doHashOp(opHGet, hashTableName, key)
 }
 
 func (c *Client) HMGet(hashTableName string, keys ...interface{}) ([]interface{}, error) {
-	return c.doHashOp(opHMGet, hashTableName, keys...)
+	return redis.Values(c.doHashOp(opHMGet, hashTableName, keys...))
 }
 
 func (c *Client) HDel(hashTableName string, key interface{}) (interface{}, error) {
-	return byKeyOp(c, opHDel, hashTableName, key)
+	return c.doHashOp(opHDel, hashTableName, key)
 }
 
-// HPop performs a pop which is a combination of `HGet` and `HDel` from a HashTable
+// HPop performs a pop which is a combination of `HGet` and `HDel` from a
+// HashTable, atomically via a Lua script so a concurrent popper can never
+// observe the value after it's already been deleted out from under them.
 func (c *Client) HPop(hashTableName string, key interface{}) (interface{}, error) {
-	// HPop is a combination of HGet+HDel, in that order
-	retrieved, err := c.HGet(hashTableName, key)
-	if err != nil {
-		return nil, err
-	}
-	if _, err := c.HDel(hashTableName, key); err != nil {
-		return nil, err
-	}
-	return retrieved, nil
+	return hpopScript.Do(c, context.Background(), hashTableName, key)
 }
 
-// HMove moves the contents keyed by a key from hashTableName1 to hashTableName2
+// HMove moves the contents keyed by a key from hashTableName1 to
+// hashTableName2, atomically via a Lua script.
 func (c *Client) HMove(hashTableName1, hashTableName2 string, key interface{}) (interface{}, error) {
-	table1Entry, err := c.HPop(hashTableName1, key)
-	if err != nil {
-		return nil, err
-	}
-
-	if _, err := c.HSet(hashTableName2, key, table1Entry); err != nil {
-		return nil, err
-	}
-	return table1Entry, nil
+	return hmoveScript.Do(c, context.Background(), hashTableName1, hashTableName2, key)
 }
 
 func (c *Client) HKeys(hashTableName string) ([]interface{}, error) {
-	return c.doHashOp(opHKeys, hashTableName)
+	return redis.Values(c.doHashOp(opHKeys, hashTableName))
 }
 
 func (c *Client) HLen(hashTableName string) (int64, error) {
-	replies, err := c.doHashOp(opHLen, hashTableName)
+	reply, err := c.doHashOp(opHLen, hashTableName)
 	if err != nil {
 		return 0, err
 	}
 
-	first := replies[0]
-	if count, ok := first.(int64); ok {
+	if count, ok := reply.(int64); ok {
 		return count, nil
 	}
 
-	vStr := fmt.Sprintf("%v", first)
+	vStr := fmt.Sprintf("%v", reply)
 	return strconv.ParseInt(vStr, 10, 64)
 }
 
@@ -242,33 +212,32 @@ func (c *Client) HLen(hashTableName string) (int64, error) {
 // collection name, where any of the types are:
 // hash, set, sorted set, list.
 func (c *Client) Del(firstTable string, otherTables ...interface{}) (interface{}, error) {
-	return byKeyOp(c, opDel, firstTable, otherTables...)
+	return c.doHashOp(opDel, firstTable, otherTables...)
 }
 
 func (c *Client) HExists(hashTableName string, key interface{}) (bool, error) {
-	replies, err := c.doHashOp(opHExists, hashTableName, key)
+	reply, err := c.doHashOp(opHExists, hashTableName, key)
 	if err != nil {
 		return false, err
 	}
 
-	first := replies[0]
-	if existance, ok := first.(bool); ok {
+	if existance, ok := reply.(bool); ok {
 		return existance, nil
 	}
 
-	return strconv.ParseBool(fmt.Sprintf("%v", first))
+	return strconv.ParseBool(fmt.Sprintf("%v", reply))
 }
 
 func (c *Client) LPush(tableName string, values ...interface{}) (interface{}, error) {
-	return byKeyOp(c, opLPush, tableName, values...)
+	return c.doHashOp(opLPush, tableName, values...)
 }
 
 func (c *Client) LPop(tableName string) (interface{}, error) {
-	return byKeyOp(c, opLPop, tableName)
+	return c.doHashOp(opLPop, tableName)
 }
 
 func (c *Client) LLen(tableName string) (int64, error) {
-	res, err := byKeyOp(c, opLLen, tableName)
+	res, err := c.doHashOp(opLLen, tableName)
 	if err != nil {
 		return 0, err
 	}
@@ -276,19 +245,19 @@ func (c *Client) LLen(tableName string) (int64, error) {
 }
 
 func (c *Client) LIndex(tableName string, index int64) (interface{}, error) {
-	return byKeyOp(c, opLIndex, tableName, index)
+	return c.doHashOp(opLIndex, tableName, index)
 }
 
 func (c *Client) SAdd(tableName string, items ...interface{}) (interface{}, error) {
-	return byKeyOp(c, opSAdd, tableName, items...)
+	return c.doHashOp(opSAdd, tableName, items...)
 }
 
 func (c *Client) SMembers(tableName string) (interface{}, error) {
-	return byKeyOp(c, opSMembers, tableName)
+	return c.doHashOp(opSMembers, tableName)
 }
 
 func (c *Client) SIsMember(tableName string, key interface{}) (bool, error) {
-	retr, err := byKeyOp(c, opSIsMember, tableName, key)
+	retr, err := c.doHashOp(opSIsMember, tableName, key)
 	if err != nil {
 		return false, err
 	}
@@ -298,11 +267,11 @@ func (c *Client) SIsMember(tableName string, key interface{}) (bool, error) {
 
 // SPop implements Redis command SPOP and it pops just 1 element from the set.
 func (c *Client) SPop(tableName string) (interface{}, error) {
-	return byKeyOp(c, opSPop, tableName)
+	return c.doHashOp(opSPop, tableName)
 }
 
 // SRem implements Redis command SREM which removes elements from a set.
 func (c *Client) SRem(tableName string, key interface{}, otherKeys ...interface{}) (interface{}, error) {
 	keys := append([]interface{}{key}, otherKeys...)
-	return byKeyOp(c, opSRem, tableName, keys...)
+	return c.doHashOp(opSRem, tableName, keys...)
 }
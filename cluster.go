@@ -0,0 +1,413 @@
+package redtable
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+const numClusterSlots = 16384
+
+var (
+	// ErrCrossSlot is returned when a multi-key op's table names don't all
+	// hash to the same cluster slot, e.g. Del across tables without a
+	// shared {hashtag}.
+	ErrCrossSlot = errors.New("redtable: keys do not all hash to the same cluster slot")
+
+	// ErrSlotUnassigned is returned when a key's slot has no owning node,
+	// e.g. the cluster is still stabilizing after a resharding.
+	ErrSlotUnassigned = errors.New("redtable: no cluster node owns this key's slot")
+)
+
+type clusterNode struct {
+	addr   string
+	client *Client
+}
+
+// ClusterClient routes commands across a Redis Cluster. It hashes each
+// command's table name to one of the 16384 cluster hash slots (crc16(key) %
+// 16384, honoring {hashtag} braces per the cluster spec) and dispatches to
+// the *Client that owns that slot, following MOVED and ASK redirects
+// transparently. It keeps the single-node *Client as the default for
+// non-clustered deployments.
+type ClusterClient struct {
+	seedURLs []string
+
+	mu    sync.RWMutex
+	slots [numClusterSlots]*clusterNode
+	nodes map[string]*clusterNode
+}
+
+// NewCluster discovers cluster topology from seedURLs via CLUSTER SLOTS and
+// returns a ClusterClient that routes commands accordingly.
+func NewCluster(seedURLs ...string) (*ClusterClient, error) {
+	if len(seedURLs) == 0 {
+		return nil, errInvalidRedisURLs
+	}
+
+	cc := &ClusterClient{
+		seedURLs: seedURLs,
+		nodes:    make(map[string]*clusterNode),
+	}
+	if err := cc.refreshSlots(); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+// refreshSlots re-issues CLUSTER SLOTS against the first reachable seed and
+// rebuilds the slot->node map.
+func (cc *ClusterClient) refreshSlots() error {
+	var lastErr error
+	for _, seed := range cc.seedURLs {
+		seedClient, err := New(seed)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		reply, err := seedClient.conn().Do("CLUSTER", "SLOTS")
+		seedClient.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		slotRanges, err := redis.Values(reply, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		cc.mu.Lock()
+		var newSlots [numClusterSlots]*clusterNode
+		for _, rawRange := range slotRanges {
+			entry, err := redis.Values(rawRange, nil)
+			if err != nil || len(entry) < 3 {
+				continue
+			}
+			start, err1 := redis.Int(entry[0], nil)
+			end, err2 := redis.Int(entry[1], nil)
+			master, err3 := redis.Values(entry[2], nil)
+			if err1 != nil || err2 != nil || err3 != nil || len(master) < 2 {
+				continue
+			}
+			ip, err4 := redis.String(master[0], nil)
+			port, err5 := redis.Int(master[1], nil)
+			if err4 != nil || err5 != nil {
+				continue
+			}
+
+			node := cc.nodeForAddrLocked(fmt.Sprintf("%s:%d", ip, port))
+			if node == nil {
+				continue
+			}
+			for slot := start; slot <= end && slot < numClusterSlots; slot++ {
+				newSlots[slot] = node
+			}
+		}
+		cc.slots = newSlots
+		cc.mu.Unlock()
+		return nil
+	}
+	return lastErr
+}
+
+// nodeForAddrLocked returns the cached node for addr, dialing and caching a
+// new one if necessary. Callers must hold cc.mu.
+func (cc *ClusterClient) nodeForAddrLocked(addr string) *clusterNode {
+	if node, ok := cc.nodes[addr]; ok {
+		return node
+	}
+	nodeClient, err := New("redis://" + addr)
+	if err != nil {
+		return nil
+	}
+	node := &clusterNode{addr: addr, client: nodeClient}
+	cc.nodes[addr] = node
+	return node
+}
+
+// keyHashSlot computes the cluster hash slot for key, honoring {hashtag}
+// braces: when key contains a non-empty `{tag}`, only tag is hashed.
+func keyHashSlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			if tag := key[start+1 : start+1+end]; tag != "" {
+				key = tag
+			}
+		}
+	}
+	return crc16(key) % numClusterSlots
+}
+
+// crc16 is the CRC16-CCITT (XModem) variant used by Redis Cluster to map
+// keys to hash slots.
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// sameSlot reports whether every key hashes to the same cluster slot.
+func sameSlot(keys ...string) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	slot := keyHashSlot(keys[0])
+	for _, k := range keys[1:] {
+		if keyHashSlot(k) != slot {
+			return false
+		}
+	}
+	return true
+}
+
+func (cc *ClusterClient) clientFor(key string) (*Client, error) {
+	slot := keyHashSlot(key)
+	cc.mu.RLock()
+	node := cc.slots[slot]
+	cc.mu.RUnlock()
+	if node == nil {
+		return nil, ErrSlotUnassigned
+	}
+	return node.client, nil
+}
+
+// parseRedirect extracts the slot and address from a MOVED/ASK error reply,
+// e.g. "MOVED 3999 127.0.0.1:6381" or "ASK 3999 127.0.0.1:6381".
+func parseRedirect(err error) (slot, addr string, ask, ok bool) {
+	fields := strings.Fields(err.Error())
+	if len(fields) != 3 {
+		return "", "", false, false
+	}
+	switch fields[0] {
+	case "MOVED":
+		return fields[1], fields[2], false, true
+	case "ASK":
+		return fields[1], fields[2], true, true
+	}
+	return "", "", false, false
+}
+
+// exec routes fn to the node owning key's slot, following a single level of
+// MOVED/ASK redirection. On MOVED it updates the slot map so later calls go
+// straight to the new owner; on ASK it issues ASKING on the target node
+// before retrying, without updating the slot map.
+func (cc *ClusterClient) exec(key string, fn func(*Client) (interface{}, error)) (interface{}, error) {
+	client, err := cc.clientFor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := fn(client)
+	if err == nil {
+		return reply, nil
+	}
+
+	slotStr, addr, ask, redirected := parseRedirect(err)
+	if !redirected {
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	target := cc.nodeForAddrLocked(addr)
+	if target == nil {
+		cc.mu.Unlock()
+		return nil, err
+	}
+	if !ask {
+		if slot, convErr := strconv.Atoi(slotStr); convErr == nil && slot >= 0 && slot < numClusterSlots {
+			cc.slots[slot] = target
+		}
+	}
+	cc.mu.Unlock()
+
+	if ask {
+		if _, err := target.client.conn().Do("ASKING"); err != nil {
+			return nil, err
+		}
+	}
+	return fn(target.client)
+}
+
+func (cc *ClusterClient) HSet(tableName string, key, value interface{}) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HSet(tableName, key, value)
+	})
+}
+
+func (cc *ClusterClient) HGet(tableName string, key interface{}) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HGet(tableName, key)
+	})
+}
+
+func (cc *ClusterClient) HMGet(tableName string, keys ...interface{}) ([]interface{}, error) {
+	reply, err := cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HMGet(tableName, keys...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.([]interface{}), nil
+}
+
+func (cc *ClusterClient) HDel(tableName string, key interface{}) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HDel(tableName, key)
+	})
+}
+
+func (cc *ClusterClient) HPop(tableName string, key interface{}) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HPop(tableName, key)
+	})
+}
+
+// HMove moves a hash entry between two tables that must share a cluster
+// slot (e.g. via a common {hashtag}); otherwise it returns ErrCrossSlot.
+func (cc *ClusterClient) HMove(hashTableName1, hashTableName2 string, key interface{}) (interface{}, error) {
+	if !sameSlot(hashTableName1, hashTableName2) {
+		return nil, ErrCrossSlot
+	}
+	return cc.exec(hashTableName1, func(c *Client) (interface{}, error) {
+		return c.HMove(hashTableName1, hashTableName2, key)
+	})
+}
+
+func (cc *ClusterClient) HKeys(tableName string) ([]interface{}, error) {
+	reply, err := cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HKeys(tableName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.([]interface{}), nil
+}
+
+func (cc *ClusterClient) HLen(tableName string) (int64, error) {
+	reply, err := cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HLen(tableName)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reply.(int64), nil
+}
+
+func (cc *ClusterClient) HExists(tableName string, key interface{}) (bool, error) {
+	reply, err := cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.HExists(tableName, key)
+	})
+	if err != nil {
+		return false, err
+	}
+	return reply.(bool), nil
+}
+
+// Del deletes one or more collections. All table names must share a
+// cluster slot (e.g. via a common {hashtag}); otherwise it returns
+// ErrCrossSlot.
+func (cc *ClusterClient) Del(firstTable string, otherTables ...interface{}) (interface{}, error) {
+	tableNames := make([]string, 0, 1+len(otherTables))
+	tableNames = append(tableNames, firstTable)
+	for _, t := range otherTables {
+		tableNames = append(tableNames, fmt.Sprintf("%v", t))
+	}
+	if !sameSlot(tableNames...) {
+		return nil, ErrCrossSlot
+	}
+	return cc.exec(firstTable, func(c *Client) (interface{}, error) {
+		return c.Del(firstTable, otherTables...)
+	})
+}
+
+func (cc *ClusterClient) LPush(tableName string, values ...interface{}) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.LPush(tableName, values...)
+	})
+}
+
+func (cc *ClusterClient) LPop(tableName string) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.LPop(tableName)
+	})
+}
+
+func (cc *ClusterClient) LLen(tableName string) (int64, error) {
+	reply, err := cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.LLen(tableName)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reply.(int64), nil
+}
+
+func (cc *ClusterClient) LIndex(tableName string, index int64) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.LIndex(tableName, index)
+	})
+}
+
+func (cc *ClusterClient) SAdd(tableName string, items ...interface{}) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.SAdd(tableName, items...)
+	})
+}
+
+func (cc *ClusterClient) SMembers(tableName string) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.SMembers(tableName)
+	})
+}
+
+func (cc *ClusterClient) SIsMember(tableName string, key interface{}) (bool, error) {
+	reply, err := cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.SIsMember(tableName, key)
+	})
+	if err != nil {
+		return false, err
+	}
+	return reply.(bool), nil
+}
+
+func (cc *ClusterClient) SPop(tableName string) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.SPop(tableName)
+	})
+}
+
+func (cc *ClusterClient) SRem(tableName string, key interface{}, otherKeys ...interface{}) (interface{}, error) {
+	return cc.exec(tableName, func(c *Client) (interface{}, error) {
+		return c.SRem(tableName, key, otherKeys...)
+	})
+}
+
+// Close closes every node connection pool the ClusterClient has dialed.
+func (cc *ClusterClient) Close() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	var lastErr error
+	for _, node := range cc.nodes {
+		if err := node.client.Close(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
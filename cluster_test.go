@@ -0,0 +1,31 @@
+package redtable
+
+import "testing"
+
+func TestCRC16CheckValue(t *testing.T) {
+	// "123456789" is the standard CRC16/XMODEM check value, 0x31C3.
+	if got, want := crc16("123456789"), uint16(0x31C3); got != want {
+		t.Errorf("crc16(123456789) = %#x, want %#x", got, want)
+	}
+}
+
+func TestKeyHashSlotHashtag(t *testing.T) {
+	a := keyHashSlot("{user1000}.following")
+	b := keyHashSlot("{user1000}.followers")
+	if a != b {
+		t.Errorf("keys sharing a {hashtag} hashed to different slots: %d != %d", a, b)
+	}
+
+	if got := keyHashSlot("{user1000}.following"); got != keyHashSlot("user1000") {
+		t.Errorf("hashtag slot %d should match slot of the bare tag %d", got, keyHashSlot("user1000"))
+	}
+}
+
+func TestSameSlot(t *testing.T) {
+	if !sameSlot("{tag}.a", "{tag}.b", "{tag}.c") {
+		t.Errorf("keys sharing a {hashtag} should be reported as the same slot")
+	}
+	if sameSlot("tableA", "tableB") {
+		t.Errorf("unrelated table names should not usually land on the same slot")
+	}
+}
@@ -0,0 +1,174 @@
+package redtable
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// doWithContext runs cmd on conn honoring ctx's deadline and cancellation.
+// When conn implements redis.ConnWithTimeout and ctx carries a deadline, the
+// command is issued with that deadline via DoWithTimeout. Otherwise the
+// command runs on conn as usual, but a cancelled or expired ctx wins the
+// race and ctx.Err() is returned instead of blocking for the reply.
+//
+// conn is shared with (and reused by) the owning *Client, so if ctx wins
+// the race, conn is closed before returning: the orphaned conn.Do is still
+// in flight on it, and handing the same conn back to Client.conn() for
+// reuse would let that stale call interleave its write/read with whatever
+// the next caller sends, silently corrupting both replies. Closing conn
+// makes the orphaned call fail fast and marks conn so Client.conn() redials
+// instead of reusing it.
+func doWithContext(ctx context.Context, conn redis.Conn, cmd string, args ...interface{}) (interface{}, error) {
+	if ctx == nil {
+		return conn.Do(cmd, args...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if cwt, ok := conn.(redis.ConnWithTimeout); ok {
+			return cwt.DoWithTimeout(time.Until(deadline), cmd, args...)
+		}
+	}
+
+	type result struct {
+		reply interface{}
+		err   error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		reply, err := conn.Do(cmd, args...)
+		resultCh <- result{reply, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		conn.Close()
+		return nil, ctx.Err()
+	case res := <-resultCh:
+		return res.reply, res.err
+	}
+}
+
+// doHashOpContext is the context-aware equivalent of doHashOp: a single
+// round trip that honors ctx's deadline and cancellation.
+func (c *Client) doHashOpContext(ctx context.Context, opName, hashTableName string, args ...interface{}) (interface{}, error) {
+	allArgs := append([]interface{}{hashTableName}, args...)
+	return doWithContext(ctx, c.conn(), opName, allArgs...)
+}
+
+func (c *Client) HSetContext(ctx context.Context, hashTableName string, key, value interface{}) (interface{}, error) {
+	return c.doHashOpContext(ctx, opHSet, hashTableName, key, value)
+}
+
+func (c *Client) HGetContext(ctx context.Context, hashTableName string, key interface{}) (interface{}, error) {
+	return c.doHashOpContext(ctx, opHGet, hashTableName, key)
+}
+
+func (c *Client) HMGetContext(ctx context.Context, hashTableName string, keys ...interface{}) ([]interface{}, error) {
+	return redis.Values(c.doHashOpContext(ctx, opHMGet, hashTableName, keys...))
+}
+
+func (c *Client) HDelContext(ctx context.Context, hashTableName string, key interface{}) (interface{}, error) {
+	return c.doHashOpContext(ctx, opHDel, hashTableName, key)
+}
+
+// HPopContext is the context-aware equivalent of HPop.
+func (c *Client) HPopContext(ctx context.Context, hashTableName string, key interface{}) (interface{}, error) {
+	return hpopScript.Do(c, ctx, hashTableName, key)
+}
+
+// HMoveContext is the context-aware equivalent of HMove.
+func (c *Client) HMoveContext(ctx context.Context, hashTableName1, hashTableName2 string, key interface{}) (interface{}, error) {
+	return hmoveScript.Do(c, ctx, hashTableName1, hashTableName2, key)
+}
+
+func (c *Client) HKeysContext(ctx context.Context, hashTableName string) ([]interface{}, error) {
+	return redis.Values(c.doHashOpContext(ctx, opHKeys, hashTableName))
+}
+
+func (c *Client) HLenContext(ctx context.Context, hashTableName string) (int64, error) {
+	reply, err := c.doHashOpContext(ctx, opHLen, hashTableName)
+	if err != nil {
+		return 0, err
+	}
+
+	if count, ok := reply.(int64); ok {
+		return count, nil
+	}
+
+	vStr := fmt.Sprintf("%v", reply)
+	return strconv.ParseInt(vStr, 10, 64)
+}
+
+// DelContext is the context-aware equivalent of Del.
+func (c *Client) DelContext(ctx context.Context, firstTable string, otherTables ...interface{}) (interface{}, error) {
+	return c.doHashOpContext(ctx, opDel, firstTable, otherTables...)
+}
+
+func (c *Client) HExistsContext(ctx context.Context, hashTableName string, key interface{}) (bool, error) {
+	reply, err := c.doHashOpContext(ctx, opHExists, hashTableName, key)
+	if err != nil {
+		return false, err
+	}
+
+	if existance, ok := reply.(bool); ok {
+		return existance, nil
+	}
+
+	return strconv.ParseBool(fmt.Sprintf("%v", reply))
+}
+
+func (c *Client) LPushContext(ctx context.Context, tableName string, values ...interface{}) (interface{}, error) {
+	return c.doHashOpContext(ctx, opLPush, tableName, values...)
+}
+
+func (c *Client) LPopContext(ctx context.Context, tableName string) (interface{}, error) {
+	return c.doHashOpContext(ctx, opLPop, tableName)
+}
+
+func (c *Client) LLenContext(ctx context.Context, tableName string) (int64, error) {
+	res, err := c.doHashOpContext(ctx, opLLen, tableName)
+	if err != nil {
+		return 0, err
+	}
+	return res.(int64), nil
+}
+
+func (c *Client) LIndexContext(ctx context.Context, tableName string, index int64) (interface{}, error) {
+	return c.doHashOpContext(ctx, opLIndex, tableName, index)
+}
+
+func (c *Client) SAddContext(ctx context.Context, tableName string, items ...interface{}) (interface{}, error) {
+	return c.doHashOpContext(ctx, opSAdd, tableName, items...)
+}
+
+func (c *Client) SMembersContext(ctx context.Context, tableName string) (interface{}, error) {
+	return c.doHashOpContext(ctx, opSMembers, tableName)
+}
+
+func (c *Client) SIsMemberContext(ctx context.Context, tableName string, key interface{}) (bool, error) {
+	retr, err := c.doHashOpContext(ctx, opSIsMember, tableName, key)
+	if err != nil {
+		return false, err
+	}
+	value := retr.(int64)
+	return value >= 1, nil
+}
+
+// SPopContext is the context-aware equivalent of SPop.
+func (c *Client) SPopContext(ctx context.Context, tableName string) (interface{}, error) {
+	return c.doHashOpContext(ctx, opSPop, tableName)
+}
+
+// SRemContext is the context-aware equivalent of SRem.
+func (c *Client) SRemContext(ctx context.Context, tableName string, key interface{}, otherKeys ...interface{}) (interface{}, error) {
+	keys := append([]interface{}{key}, otherKeys...)
+	return c.doHashOpContext(ctx, opSRem, tableName, keys...)
+}
@@ -0,0 +1,113 @@
+package redtable
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/odeke-em/go-uuid"
+)
+
+func TestHSetGetContext(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	ctx := context.Background()
+	if _, err := client.HSetContext(ctx, tableName, "k1", "v1"); err != nil {
+		t.Fatalf("HSetContext err=%v", err)
+	}
+
+	got, err := client.HGetContext(ctx, tableName, "k1")
+	if err != nil {
+		t.Fatalf("HGetContext err=%v", err)
+	}
+	if want := "v1"; fmt.Sprintf("%s", got) != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+}
+
+func TestHGetContextCancelled(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.HGetContext(ctx, tableName, "k1"); err != context.Canceled {
+		t.Errorf("err=%v want=%v", err, context.Canceled)
+	}
+}
+
+// TestDoWithContextCancelMidFlightInvalidatesConn exercises the no-deadline
+// cancellation race in doWithContext directly: it cancels ctx while a
+// blocking command is still in flight on the connection, and asserts that
+// the shared connection is closed (rather than silently handed back for
+// reuse while the orphaned call is still running on it) and that the
+// Client transparently redials on its next call.
+func TestDoWithContextCancelMidFlightInvalidatesConn(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	conn := client.conn()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	// BLPOP blocks for up to 2s on an empty list, well past when cancel
+	// fires, so this exercises the goroutine-race branch of
+	// doWithContext (ctx carries no deadline, so the ConnWithTimeout
+	// fast path is skipped).
+	if _, err := doWithContext(ctx, conn, "BLPOP", tableName, 2); err != context.Canceled {
+		t.Fatalf("err=%v want=%v", err, context.Canceled)
+	}
+
+	if conn.Err() == nil {
+		t.Errorf("expected the in-flight connection to be invalidated after a mid-flight cancel")
+	}
+
+	// The Client must not hand this connection back out; it should redial.
+	if _, err := client.HSetContext(context.Background(), tableName, "k1", "v1"); err != nil {
+		t.Fatalf("HSetContext after cancel err=%v", err)
+	}
+}
+
+func TestHSetContextDeadlineExceeded(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Nanosecond)
+	defer cancel()
+
+	time.Sleep(1 * time.Millisecond)
+	if _, err := client.HSetContext(ctx, tableName, "k1", "v1"); err == nil {
+		t.Errorf("expected a context deadline error, got nil")
+	}
+}
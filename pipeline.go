@@ -0,0 +1,218 @@
+package redtable
+
+import (
+	"context"
+	"errors"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// ErrTxAborted is returned by Tx/TxContext when EXEC reports that a
+// watched key changed between WATCH and EXEC, aborting the transaction.
+var ErrTxAborted = errors.New("redtable: transaction aborted, a watched key changed")
+
+// maxTxRetries bounds how many times Tx/TxContext will re-run fn after
+// an optimistic-concurrency abort before giving up.
+const maxTxRetries = 3
+
+// Reply is a single queued command's result from a Pipeline or Tx Exec.
+type Reply struct {
+	Value interface{}
+	Err   error
+}
+
+type pipelineCmd struct {
+	opName string
+	args   []interface{}
+}
+
+// Pipeline queues commands to be flushed to the server in a single network
+// round trip via conn.Send/Flush/Receive, instead of one round trip per
+// command. Use Client.Pipeline to create one.
+type Pipeline struct {
+	client *Client
+	cmds   []pipelineCmd
+}
+
+// Pipeline returns a new Pipeline builder bound to c.
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c}
+}
+
+func (p *Pipeline) queue(opName, tableName string, args ...interface{}) *Pipeline {
+	allArgs := append([]interface{}{tableName}, args...)
+	p.cmds = append(p.cmds, pipelineCmd{opName: opName, args: allArgs})
+	return p
+}
+
+func (p *Pipeline) HSet(tableName string, key, value interface{}) *Pipeline {
+	return p.queue(opHSet, tableName, key, value)
+}
+
+func (p *Pipeline) HGet(tableName string, key interface{}) *Pipeline {
+	return p.queue(opHGet, tableName, key)
+}
+
+func (p *Pipeline) HDel(tableName string, key interface{}) *Pipeline {
+	return p.queue(opHDel, tableName, key)
+}
+
+func (p *Pipeline) LPush(tableName string, values ...interface{}) *Pipeline {
+	return p.queue(opLPush, tableName, values...)
+}
+
+func (p *Pipeline) SAdd(tableName string, items ...interface{}) *Pipeline {
+	return p.queue(opSAdd, tableName, items...)
+}
+
+// Exec flushes all queued commands in a single round trip and returns their
+// replies, in the order they were queued.
+func (p *Pipeline) Exec(ctx context.Context) ([]Reply, error) {
+	return p.exec(ctx, nil)
+}
+
+// exec flushes the queued commands. When watchKeys is non-nil the commands
+// are wrapped in WATCH/MULTI/EXEC so they commit atomically; a nil,
+// non-error EXEC reply means a watched key changed and is surfaced as
+// ErrTxAborted.
+//
+// exec gets its own connection from the pool rather than p.client.conn()'s
+// shared _curConn: Send/Flush/Receive span multiple round trips with no
+// lock held across them, and interleaving another caller's Send/Do on the
+// same connection in the middle of that sequence would desynchronize the
+// reply stream. A dedicated connection keeps this Pipeline/Tx's wire
+// traffic isolated from every other concurrent user of p.client.
+func (p *Pipeline) exec(ctx context.Context, watchKeys []interface{}) ([]Reply, error) {
+	conn := p.client.poolConn()
+	defer conn.Close()
+
+	transactional := watchKeys != nil
+
+	if transactional {
+		if len(watchKeys) > 0 {
+			if err := conn.Send(opWatch, watchKeys...); err != nil {
+				return nil, err
+			}
+		}
+		if err := conn.Send(opMulti); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cmd := range p.cmds {
+		if err := conn.Send(cmd.opName, cmd.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	if !transactional {
+		if err := conn.Flush(); err != nil {
+			return nil, err
+		}
+		replies := make([]Reply, len(p.cmds))
+		for i := range p.cmds {
+			v, err := conn.Receive()
+			replies[i] = Reply{Value: v, Err: err}
+		}
+		return replies, nil
+	}
+
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+	if len(watchKeys) > 0 {
+		if _, err := conn.Receive(); err != nil { // WATCH reply
+			return nil, err
+		}
+	}
+	if _, err := conn.Receive(); err != nil { // MULTI reply
+		return nil, err
+	}
+
+	for range p.cmds {
+		if _, err := conn.Receive(); err != nil { // queued reply
+			return nil, err
+		}
+	}
+
+	execReply, err := doWithContext(ctx, conn, opExec)
+	if err != nil {
+		return nil, err
+	}
+	if execReply == nil {
+		return nil, ErrTxAborted
+	}
+
+	values, err := redis.Values(execReply, nil)
+	if err != nil {
+		return nil, err
+	}
+	replies := make([]Reply, len(values))
+	for i, v := range values {
+		replies[i] = Reply{Value: v}
+	}
+	return replies, nil
+}
+
+// Tx is a Pipeline committed atomically via MULTI/EXEC, optionally WATCHing
+// keys beforehand for optimistic concurrency control. Use Client.Tx or
+// Client.TxContext to run one.
+type Tx struct {
+	pipeline *Pipeline
+}
+
+func (tx *Tx) HSet(tableName string, key, value interface{}) *Tx {
+	tx.pipeline.HSet(tableName, key, value)
+	return tx
+}
+
+func (tx *Tx) HGet(tableName string, key interface{}) *Tx {
+	tx.pipeline.HGet(tableName, key)
+	return tx
+}
+
+func (tx *Tx) HDel(tableName string, key interface{}) *Tx {
+	tx.pipeline.HDel(tableName, key)
+	return tx
+}
+
+func (tx *Tx) LPush(tableName string, values ...interface{}) *Tx {
+	tx.pipeline.LPush(tableName, values...)
+	return tx
+}
+
+func (tx *Tx) SAdd(tableName string, items ...interface{}) *Tx {
+	tx.pipeline.SAdd(tableName, items...)
+	return tx
+}
+
+// Tx runs fn against a fresh Tx bound to c and commits the commands it
+// queues atomically via MULTI/EXEC, WATCHing watchKeys beforehand. If a
+// watched key changes before EXEC, Redis aborts the transaction and Tx
+// retries fn, up to maxTxRetries times.
+func (c *Client) Tx(fn func(tx *Tx) error, watchKeys ...interface{}) ([]Reply, error) {
+	return c.TxContext(context.Background(), fn, watchKeys...)
+}
+
+// TxContext is the context-aware equivalent of Tx.
+func (c *Client) TxContext(ctx context.Context, fn func(tx *Tx) error, watchKeys ...interface{}) ([]Reply, error) {
+	if watchKeys == nil {
+		watchKeys = []interface{}{}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxTxRetries; attempt++ {
+		tx := &Tx{pipeline: &Pipeline{client: c}}
+		if err := fn(tx); err != nil {
+			return nil, err
+		}
+
+		replies, err := tx.pipeline.exec(ctx, watchKeys)
+		if err == ErrTxAborted {
+			lastErr = err
+			continue
+		}
+		return replies, err
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,64 @@
+package redtable
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/odeke-em/go-uuid"
+)
+
+func TestPipelineExec(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	replies, err := client.Pipeline().
+		HSet(tableName, "a", "1").
+		HSet(tableName, "b", "2").
+		HGet(tableName, "a").
+		Exec(context.Background())
+	if err != nil {
+		t.Fatalf("Exec err=%v", err)
+	}
+
+	if got := len(replies); got != 3 {
+		t.Fatalf("got %d replies, want 3", got)
+	}
+	if got, want := fmt.Sprintf("%s", replies[2].Value), "1"; got != want {
+		t.Errorf("HGet reply got=%q want=%q", got, want)
+	}
+}
+
+func TestTxCommits(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	_, err = client.Tx(func(tx *Tx) error {
+		tx.HSet(tableName, "a", "1")
+		tx.HSet(tableName, "b", "2")
+		return nil
+	}, tableName)
+	if err != nil {
+		t.Fatalf("Tx err=%v", err)
+	}
+
+	got, err := client.HGet(tableName, "a")
+	if err != nil {
+		t.Fatalf("HGet err=%v", err)
+	}
+	if want := "1"; fmt.Sprintf("%s", got) != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+}
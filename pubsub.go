@@ -0,0 +1,158 @@
+package redtable
+
+import (
+	"context"
+	"sync"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+const opPublish = "PUBLISH"
+
+// Message is a single Pub/Sub message delivered to a Subscription, either
+// published via Client.Publish or PUBLISH from any other client. Pattern
+// is set only when the Subscription was opened with PSubscribe and the
+// message matched a glob pattern rather than an exact channel.
+type Message struct {
+	Channel string
+	Pattern string
+	Payload []byte
+}
+
+// Subscription is a live Pub/Sub subscription to one or more
+// channels/patterns. It owns a connection dedicated to receiving, since a
+// subscribed redigo connection can't run other commands. Use
+// Client.Subscribe or Client.PSubscribe to open one.
+type Subscription struct {
+	conn redis.Conn
+	psc  redis.PubSubConn
+
+	messages chan Message
+	errs     chan error
+	done     chan struct{}
+
+	closeOnce sync.Once
+}
+
+func (c *Client) newSubscription(ctx context.Context, patterned bool, channels ...string) (*Subscription, error) {
+	conn, err := c.dialFn()
+	if err != nil {
+		return nil, err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	patterns := make([]interface{}, len(channels))
+	for i, ch := range channels {
+		patterns[i] = ch
+	}
+
+	if patterned {
+		err = psc.PSubscribe(patterns...)
+	} else {
+		err = psc.Subscribe(patterns...)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	sub := &Subscription{
+		conn:     conn,
+		psc:      psc,
+		messages: make(chan Message, 64),
+		errs:     make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+	go sub.loop()
+
+	if ctx != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+			case <-sub.done:
+			}
+		}()
+	}
+
+	return sub, nil
+}
+
+// Subscribe opens a Subscription to one or more exact channels. Closing
+// ctx closes the Subscription.
+func (c *Client) Subscribe(ctx context.Context, channels ...string) (*Subscription, error) {
+	return c.newSubscription(ctx, false, channels...)
+}
+
+// PSubscribe opens a Subscription to one or more glob-style channel
+// patterns. Closing ctx closes the Subscription.
+func (c *Client) PSubscribe(ctx context.Context, patterns ...string) (*Subscription, error) {
+	return c.newSubscription(ctx, true, patterns...)
+}
+
+func (s *Subscription) loop() {
+	defer close(s.messages)
+	for {
+		switch v := s.psc.Receive().(type) {
+		case redis.Message:
+			select {
+			case s.messages <- Message{Channel: v.Channel, Payload: v.Data}:
+			case <-s.done:
+				return
+			}
+		case redis.PMessage:
+			select {
+			case s.messages <- Message{Channel: v.Channel, Pattern: v.Pattern, Payload: v.Data}:
+			case <-s.done:
+				return
+			}
+		case error:
+			select {
+			case <-s.done:
+				// The error came from Close tearing down the connection.
+				return
+			default:
+			}
+			select {
+			case s.errs <- v:
+			default:
+			}
+			return
+		}
+	}
+}
+
+// Messages returns the channel Pub/Sub messages are delivered on. It is
+// closed once the Subscription is closed or its connection errors.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Errs surfaces connection/protocol errors hit while receiving, on a
+// separate channel from Messages so callers can tell a clean Close from
+// an underlying failure.
+func (s *Subscription) Errs() <-chan error {
+	return s.errs
+}
+
+// Close unsubscribes from everything and closes the dedicated connection.
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.psc.Unsubscribe()
+		if pErr := s.psc.PUnsubscribe(); pErr != nil && err == nil {
+			err = pErr
+		}
+		if cErr := s.conn.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	})
+	return err
+}
+
+// Publish publishes payload to channel and returns the number of clients
+// that received it.
+func (c *Client) Publish(channel string, payload interface{}) (int64, error) {
+	return redis.Int64(c.conn().Do(opPublish, channel, payload))
+}
@@ -0,0 +1,49 @@
+package redtable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odeke-em/go-uuid"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	channel := uuid.NewRandom().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub, err := client.Subscribe(ctx, channel)
+	if err != nil {
+		t.Fatalf("Subscribe err=%v", err)
+	}
+	defer sub.Close()
+
+	// Give the subscribe confirmation a moment to land before publishing.
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := client.Publish(channel, "hello"); err != nil {
+		t.Fatalf("Publish err=%v", err)
+	}
+
+	select {
+	case msg := <-sub.Messages():
+		if got, want := msg.Channel, channel; got != want {
+			t.Errorf("Channel got=%q want=%q", got, want)
+		}
+		if got, want := string(msg.Payload), "hello"; got != want {
+			t.Errorf("Payload got=%q want=%q", got, want)
+		}
+	case err := <-sub.Errs():
+		t.Fatalf("subscription err=%v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+}
@@ -0,0 +1,124 @@
+package redtable
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	opEval    = "EVAL"
+	opEvalSha = "EVALSHA"
+	opScript  = "SCRIPT"
+)
+
+// Script is a Lua script that redtable runs via EVALSHA, computing its
+// SHA1 once at construction and falling back to EVAL (which makes Redis
+// cache the script under that SHA1 too) on a NOSCRIPT reply. This unlocks
+// atomic compound ops - conditional HSet, rate limiters, queue-with-dedupe
+// - that HGet+HDel-style round trips can only fake racily.
+type Script struct {
+	keyCount int
+	src      string
+	sha1     string
+}
+
+// NewScript returns a Script for src, which expects keyCount KEYS
+// arguments followed by any number of ARGV arguments.
+func NewScript(keyCount int, src string) *Script {
+	sum := sha1.Sum([]byte(src))
+	return &Script{
+		keyCount: keyCount,
+		src:      src,
+		sha1:     hex.EncodeToString(sum[:]),
+	}
+}
+
+// Load preloads src onto c via SCRIPT LOAD, so the first Do against c
+// doesn't pay for a NOSCRIPT round trip.
+func (s *Script) Load(c *Client) error {
+	_, err := c.conn().Do(opScript, "LOAD", s.src)
+	return err
+}
+
+// Do runs the script on c, passing keysAndArgs as its KEYS followed by its
+// ARGV. It tries EVALSHA first; on a NOSCRIPT reply it falls back to EVAL.
+func (s *Script) Do(c *Client, ctx context.Context, keysAndArgs ...interface{}) (interface{}, error) {
+	reply, err := doWithContext(ctx, c.conn(), opEvalSha, s.evalArgs(s.sha1, keysAndArgs)...)
+	if err == nil {
+		return reply, nil
+	}
+	if !isNoScript(err) {
+		return nil, err
+	}
+	return doWithContext(ctx, c.conn(), opEval, s.evalArgs(s.src, keysAndArgs)...)
+}
+
+func (s *Script) evalArgs(srcOrSha string, keysAndArgs []interface{}) []interface{} {
+	args := make([]interface{}, 0, 2+len(keysAndArgs))
+	args = append(args, srcOrSha, s.keyCount)
+	return append(args, keysAndArgs...)
+}
+
+func isNoScript(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT")
+}
+
+// LoadOnCluster preloads s, via SCRIPT LOAD, on every master node cc
+// currently knows about.
+func (s *Script) LoadOnCluster(cc *ClusterClient) error {
+	cc.mu.RLock()
+	nodes := make([]*clusterNode, 0, len(cc.nodes))
+	for _, node := range cc.nodes {
+		nodes = append(nodes, node)
+	}
+	cc.mu.RUnlock()
+
+	var lastErr error
+	for _, node := range nodes {
+		if err := s.Load(node.client); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// DoOnCluster routes s to the node owning the slot of keysAndArgs' first
+// KEYS entry, the same way ClusterClient routes its other ops by table
+// name.
+func (s *Script) DoOnCluster(cc *ClusterClient, ctx context.Context, keysAndArgs ...interface{}) (interface{}, error) {
+	if s.keyCount == 0 || len(keysAndArgs) == 0 {
+		return nil, ErrSlotUnassigned
+	}
+
+	firstKey, ok := keysAndArgs[0].(string)
+	if !ok {
+		firstKey = fmt.Sprintf("%v", keysAndArgs[0])
+	}
+
+	return cc.exec(firstKey, func(c *Client) (interface{}, error) {
+		return s.Do(c, ctx, keysAndArgs...)
+	})
+}
+
+// hpopScript atomically HGETs then HDELs a field, so HPop can't race a
+// concurrent popper the way a separate HGet+HDel could.
+var hpopScript = NewScript(1, `
+local v = redis.call('HGET', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[1], ARGV[1])
+return v
+`)
+
+// hmoveScript atomically moves a hash field from one table to another, so
+// HMove can't lose or duplicate the value the way a separate
+// HPop+HSet could.
+var hmoveScript = NewScript(2, `
+local v = redis.call('HGET', KEYS[1], ARGV[1])
+if v then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	redis.call('HSET', KEYS[2], ARGV[1], v)
+end
+return v
+`)
@@ -0,0 +1,72 @@
+package redtable
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/odeke-em/go-uuid"
+)
+
+func TestScriptEvalShaFallback(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	script := NewScript(1, `return redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])`)
+
+	// No Load call: the first Do should hit NOSCRIPT and fall back to EVAL.
+	if _, err := script.Do(client, context.Background(), tableName, "k1", "v1"); err != nil {
+		t.Fatalf("Do err=%v", err)
+	}
+
+	got, err := client.HGet(tableName, "k1")
+	if err != nil {
+		t.Fatalf("HGet err=%v", err)
+	}
+	if want := "v1"; fmt.Sprintf("%s", got) != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+
+	// Now that EVAL has made the server cache the script, EVALSHA should
+	// succeed directly.
+	if _, err := script.Do(client, context.Background(), tableName, "k2", "v2"); err != nil {
+		t.Fatalf("Do (post-cache) err=%v", err)
+	}
+}
+
+func TestHPopHMoveAtomic(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	table1, table2 := uuid.NewRandom().String(), uuid.NewRandom().String()
+	defer client.Del(table1, table2)
+
+	if _, err := client.HSet(table1, "k1", "v1"); err != nil {
+		t.Fatalf("HSet err=%v", err)
+	}
+
+	if _, err := client.HMove(table1, table2, "k1"); err != nil {
+		t.Fatalf("HMove err=%v", err)
+	}
+
+	if exists, err := client.HExists(table1, "k1"); err != nil || exists {
+		t.Errorf("k1 should no longer exist in table1: exists=%v err=%v", exists, err)
+	}
+
+	got, err := client.HGet(table2, "k1")
+	if err != nil {
+		t.Fatalf("HGet err=%v", err)
+	}
+	if want := "v1"; fmt.Sprintf("%s", got) != want {
+		t.Errorf("got=%q want=%q", got, want)
+	}
+}
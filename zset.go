@@ -0,0 +1,160 @@
+package redtable
+
+import (
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+const (
+	opZAdd          = "ZADD"
+	opZRange        = "ZRANGE"
+	opZRevRange     = "ZREVRANGE"
+	opZRangeByScore = "ZRANGEBYSCORE"
+	opZIncrBy       = "ZINCRBY"
+	opZRank         = "ZRANK"
+	opZScore        = "ZSCORE"
+	opZRem          = "ZREM"
+	opZCard         = "ZCARD"
+	opZUnionStore   = "ZUNIONSTORE"
+	opZInterStore   = "ZINTERSTORE"
+)
+
+// ScoredMember pairs a sorted-set member with its score, as returned by
+// range scans like ZRange, ZRevRange and ZRangeByScore.
+type ScoredMember struct {
+	Score  float64
+	Member interface{}
+}
+
+// ZAdd adds one or more scored members to the sorted set tableName.
+func (c *Client) ZAdd(tableName string, members ...ScoredMember) (interface{}, error) {
+	args := make([]interface{}, 0, len(members)*2)
+	for _, m := range members {
+		args = append(args, m.Score, m.Member)
+	}
+	return c.doHashOp(opZAdd, tableName, args...)
+}
+
+// ZIncrBy increments member's score in the sorted set tableName by
+// increment, and returns its new score.
+func (c *Client) ZIncrBy(tableName string, increment float64, member interface{}) (float64, error) {
+	return redis.Float64(c.doHashOp(opZIncrBy, tableName, increment, member))
+}
+
+// ZRank returns member's zero-based rank in tableName, ordered by
+// ascending score.
+func (c *Client) ZRank(tableName string, member interface{}) (int64, error) {
+	return redis.Int64(c.doHashOp(opZRank, tableName, member))
+}
+
+// ZScore returns member's score in the sorted set tableName.
+func (c *Client) ZScore(tableName string, member interface{}) (float64, error) {
+	return redis.Float64(c.doHashOp(opZScore, tableName, member))
+}
+
+// ZRem removes one or more members from the sorted set tableName.
+func (c *Client) ZRem(tableName string, member interface{}, otherMembers ...interface{}) (interface{}, error) {
+	members := append([]interface{}{member}, otherMembers...)
+	return c.doHashOp(opZRem, tableName, members...)
+}
+
+// ZCard returns the cardinality of the sorted set tableName.
+func (c *Client) ZCard(tableName string) (int64, error) {
+	return redis.Int64(c.doHashOp(opZCard, tableName))
+}
+
+func (c *Client) zRangeGeneric(opName, tableName string, start, stop int64) ([]ScoredMember, error) {
+	values, err := redis.Values(c.doHashOp(opName, tableName, start, stop, "WITHSCORES"))
+	if err != nil {
+		return nil, err
+	}
+	return scoredMembersFromReply(values)
+}
+
+// ZRange returns the members of the sorted set tableName within
+// [start, stop] by ascending score, along with their scores.
+func (c *Client) ZRange(tableName string, start, stop int64) ([]ScoredMember, error) {
+	return c.zRangeGeneric(opZRange, tableName, start, stop)
+}
+
+// ZRevRange returns the members of the sorted set tableName within
+// [start, stop] by descending score, along with their scores.
+func (c *Client) ZRevRange(tableName string, start, stop int64) ([]ScoredMember, error) {
+	return c.zRangeGeneric(opZRevRange, tableName, start, stop)
+}
+
+// ZRangeByScoreOption configures optional ZRANGEBYSCORE modifiers such as
+// LIMIT.
+type ZRangeByScoreOption func(args []interface{}) []interface{}
+
+// ZRangeByScoreLimit adds a LIMIT offset count clause to a ZRangeByScore
+// call.
+func ZRangeByScoreLimit(offset, count int64) ZRangeByScoreOption {
+	return func(args []interface{}) []interface{} {
+		return append(args, "LIMIT", offset, count)
+	}
+}
+
+// ZRangeByScore returns the members of the sorted set tableName with score
+// in [min, max], along with their scores.
+func (c *Client) ZRangeByScore(tableName string, min, max float64, opts ...ZRangeByScoreOption) ([]ScoredMember, error) {
+	args := []interface{}{min, max, "WITHSCORES"}
+	for _, opt := range opts {
+		args = opt(args)
+	}
+
+	values, err := redis.Values(c.doHashOp(opZRangeByScore, tableName, args...))
+	if err != nil {
+		return nil, err
+	}
+	return scoredMembersFromReply(values)
+}
+
+// scoredMembersFromReply parses the paired [member, score, member, score, …]
+// reply redigo returns for a WITHSCORES range scan into ScoredMembers.
+func scoredMembersFromReply(values []interface{}) ([]ScoredMember, error) {
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("redtable: odd number of WITHSCORES reply elements: %d", len(values))
+	}
+
+	members := make([]ScoredMember, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		score, err := redis.Float64(values[i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, ScoredMember{Member: values[i], Score: score})
+	}
+	return members, nil
+}
+
+func (c *Client) zStore(opName, destTable string, keys []string, weights []float64, aggregate string) (int64, error) {
+	args := make([]interface{}, 0, len(keys)+len(weights)+4)
+	args = append(args, len(keys))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	if len(weights) > 0 {
+		args = append(args, "WEIGHTS")
+		for _, w := range weights {
+			args = append(args, w)
+		}
+	}
+	if aggregate != "" {
+		args = append(args, "AGGREGATE", aggregate)
+	}
+	return redis.Int64(c.doHashOp(opName, destTable, args...))
+}
+
+// ZUnionStore computes the weighted union of keys and stores the result
+// in dest, returning the cardinality of the resulting sorted set.
+func (c *Client) ZUnionStore(dest string, keys []string, weights []float64, aggregate string) (int64, error) {
+	return c.zStore(opZUnionStore, dest, keys, weights, aggregate)
+}
+
+// ZInterStore computes the weighted intersection of keys and stores the
+// result in dest, returning the cardinality of the resulting sorted set.
+func (c *Client) ZInterStore(dest string, keys []string, weights []float64, aggregate string) (int64, error) {
+	return c.zStore(opZInterStore, dest, keys, weights, aggregate)
+}
@@ -0,0 +1,86 @@
+package redtable
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/odeke-em/go-uuid"
+)
+
+func TestZAddRangeScore(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	tableName := uuid.NewRandom().String()
+	defer client.Del(tableName)
+
+	if _, err := client.ZAdd(tableName,
+		ScoredMember{Score: 1, Member: "a"},
+		ScoredMember{Score: 2, Member: "b"},
+		ScoredMember{Score: 3, Member: "c"},
+	); err != nil {
+		t.Fatalf("ZAdd err=%v", err)
+	}
+
+	members, err := client.ZRange(tableName, 0, -1)
+	if err != nil {
+		t.Fatalf("ZRange err=%v", err)
+	}
+	if got, want := len(members), 3; got != want {
+		t.Fatalf("got %d members, want %d", got, want)
+	}
+	if got, want := members[0].Member, "a"; fmt.Sprintf("%s", got) != want {
+		t.Errorf("lowest score member got=%v want=%v", got, want)
+	}
+
+	score, err := client.ZScore(tableName, "b")
+	if err != nil {
+		t.Fatalf("ZScore err=%v", err)
+	}
+	if got, want := score, 2.0; got != want {
+		t.Errorf("ZScore got=%v want=%v", got, want)
+	}
+
+	card, err := client.ZCard(tableName)
+	if err != nil {
+		t.Fatalf("ZCard err=%v", err)
+	}
+	if got, want := card, int64(3); got != want {
+		t.Errorf("ZCard got=%v want=%v", got, want)
+	}
+}
+
+func TestZUnionStore(t *testing.T) {
+	client, err := newTestClient()
+	if err != nil {
+		t.Fatalf("creating client err=%v", err)
+	}
+	defer client.Close()
+
+	table1, table2, dest := uuid.NewRandom().String(), uuid.NewRandom().String(), uuid.NewRandom().String()
+	defer func() {
+		client.Del(table1, table2, dest)
+	}()
+
+	client.ZAdd(table1, ScoredMember{Score: 1, Member: "a"})
+	client.ZAdd(table2, ScoredMember{Score: 2, Member: "a"}, ScoredMember{Score: 1, Member: "b"})
+
+	card, err := client.ZUnionStore(dest, []string{table1, table2}, nil, "SUM")
+	if err != nil {
+		t.Fatalf("ZUnionStore err=%v", err)
+	}
+	if got, want := card, int64(2); got != want {
+		t.Errorf("ZUnionStore cardinality got=%v want=%v", got, want)
+	}
+
+	score, err := client.ZScore(dest, "a")
+	if err != nil {
+		t.Fatalf("ZScore err=%v", err)
+	}
+	if got, want := score, 3.0; got != want {
+		t.Errorf("summed score got=%v want=%v", got, want)
+	}
+}